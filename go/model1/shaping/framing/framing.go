@@ -0,0 +1,112 @@
+// Package framing packs variable-length records into a length-prefixed
+// byte stream, using the same little-endian uint64 length prefix per
+// record as the external EigenDA node's chunk encoding.
+package framing
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const lengthPrefixSize = 8
+
+var ErrTruncated = errors.New("framing: truncated length-prefixed chunk")
+
+// EncodeChunks concatenates chunks, each preceded by its length as a
+// little-endian uint64.
+func EncodeChunks(chunks [][]byte) []byte {
+	var size int
+	for _, c := range chunks {
+		size += lengthPrefixSize + len(c)
+	}
+
+	out := make([]byte, 0, size)
+	for _, c := range chunks {
+		var prefix [lengthPrefixSize]byte
+		binary.LittleEndian.PutUint64(prefix[:], uint64(len(c)))
+		out = append(out, prefix[:]...)
+		out = append(out, c...)
+	}
+	return out
+}
+
+// DecodeChunks reverses EncodeChunks.
+func DecodeChunks(data []byte) ([][]byte, error) {
+	var chunks [][]byte
+	for len(data) > 0 {
+		if len(data) < lengthPrefixSize {
+			return nil, ErrTruncated
+		}
+		n := binary.LittleEndian.Uint64(data[:lengthPrefixSize])
+		data = data[lengthPrefixSize:]
+
+		if uint64(len(data)) < n {
+			return nil, ErrTruncated
+		}
+		chunks = append(chunks, data[:n:n])
+		data = data[n:]
+	}
+	return chunks, nil
+}
+
+// Framer incrementally encodes chunks into the same wire format as
+// EncodeChunks, draining through Read so it composes with the (dn, sn)
+// partial-progress semantics used elsewhere in this tree.
+type Framer struct {
+	pending []byte
+}
+
+func NewFramer() *Framer {
+	return &Framer{}
+}
+
+// Put queues chunk's length-prefixed encoding for the next Read calls.
+func (f *Framer) Put(chunk []byte) {
+	var prefix [lengthPrefixSize]byte
+	binary.LittleEndian.PutUint64(prefix[:], uint64(len(chunk)))
+	f.pending = append(f.pending, prefix[:]...)
+	f.pending = append(f.pending, chunk...)
+}
+
+// Read copies as many queued framed bytes into dst as fit.
+func (f *Framer) Read(dst []byte) (n int) {
+	n = copy(dst, f.pending)
+	f.pending = f.pending[n:]
+	return n
+}
+
+func (f *Framer) Buffered() int {
+	return len(f.pending)
+}
+
+// Deframer incrementally recovers chunks from a byte stream produced by a
+// Framer or EncodeChunks, even if delivered in arbitrarily small pieces.
+type Deframer struct {
+	buf []byte
+}
+
+func NewDeframer() *Deframer {
+	return &Deframer{}
+}
+
+// Write appends src to the internal buffer, always consuming all of it.
+func (d *Deframer) Write(src []byte) (sn int) {
+	d.buf = append(d.buf, src...)
+	return len(src)
+}
+
+// Next returns the next complete chunk buffered so far. ok is false if
+// fewer bytes than a full length-prefixed chunk have arrived yet.
+func (d *Deframer) Next() (chunk []byte, ok bool) {
+	if len(d.buf) < lengthPrefixSize {
+		return nil, false
+	}
+	n := binary.LittleEndian.Uint64(d.buf[:lengthPrefixSize])
+	if uint64(len(d.buf)-lengthPrefixSize) < n {
+		return nil, false
+	}
+
+	chunk = d.buf[lengthPrefixSize : lengthPrefixSize+n : lengthPrefixSize+n]
+	d.buf = d.buf[lengthPrefixSize+n:]
+	return chunk, true
+}
@@ -0,0 +1,106 @@
+package wdist
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// sipHash24 is a SipHash-2-4 compression of a 128-bit key and a fixed
+// two-word message (m0, m1) down to a 64-bit word.
+func sipHash24(k0, k1, m0, m1 uint64) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13)
+		v1 ^= v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16)
+		v3 ^= v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21)
+		v3 ^= v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17)
+		v1 ^= v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	v3 ^= m0
+	round()
+	round()
+	v0 ^= m0
+
+	v3 ^= m1
+	round()
+	round()
+	v0 ^= m1
+
+	const lengthBlock = uint64(16) << 56 // final block: message length in bytes, no tail bytes
+	v3 ^= lengthBlock
+	round()
+	round()
+	v0 ^= lengthBlock
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+// prfStream is a keyed CSPRNG byte stream: sipHash24 run in an OFB-like
+// feedback mode, keyed on the session seed and domain-separated so the
+// length and sleep streams never collide.
+type prfStream struct {
+	k0, k1  uint64
+	domain  uint64
+	counter uint64
+	state   uint64
+	buf     [8]byte
+	pos     int
+}
+
+func newPRFStream(seed [16]byte, domain uint64) *prfStream {
+	s := &prfStream{
+		k0:     binary.LittleEndian.Uint64(seed[0:8]),
+		k1:     binary.LittleEndian.Uint64(seed[8:16]),
+		domain: domain,
+	}
+	s.pos = len(s.buf) // force refill() on the first nextByte() call
+	return s
+}
+
+func (p *prfStream) refill() {
+	p.state = sipHash24(p.k0, p.k1, p.state, p.counter^p.domain)
+	p.counter++
+	binary.LittleEndian.PutUint64(p.buf[:], p.state)
+	p.pos = 0
+}
+
+func (p *prfStream) nextByte() byte {
+	if p.pos >= len(p.buf) {
+		p.refill()
+	}
+	b := p.buf[p.pos]
+	p.pos++
+	return b
+}
+
+func (p *prfStream) uint64() uint64 {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v |= uint64(p.nextByte()) << (8 * i)
+	}
+	return v
+}
+
+func (p *prfStream) float64() float64 {
+	return float64(p.uint64()>>11) / float64(uint64(1)<<53)
+}
@@ -0,0 +1,133 @@
+// Package wdist is a ScrambleSuit-style Encoder/Decoder pair that samples
+// packet lengths and inter-arrival times from a per-session weighted
+// distribution derived from a keyed PRF.
+package wdist
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// SeedLen is the size in bytes of the session seed consumed by NewEncoder
+// and NewDecoder.
+const SeedLen = 16
+
+const (
+	supportSize = 32
+
+	domainLength = uint64(1)
+	domainSleep  = uint64(2)
+
+	maxSleep = 50 * time.Millisecond
+)
+
+// weighted is a discrete distribution over a fixed support, sampled by
+// binary search on a CDF.
+type weighted struct {
+	values []uint64
+	cdf    []float64
+}
+
+func newWeighted(stream *prfStream, low, high uint64) weighted {
+	span := high - low + 1
+	n := uint64(supportSize)
+	if span < n {
+		n = span
+	}
+
+	seen := make(map[uint64]bool, n)
+	values := make([]uint64, 0, n)
+	for uint64(len(values)) < n {
+		v := low + stream.uint64()%span
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	weights := make([]float64, len(values))
+	var total float64
+	for i := range weights {
+		w := float64(stream.uint64()>>11) + 1 // +1 so no value gets zero weight
+		weights[i] = w
+		total += w
+	}
+
+	cdf := make([]float64, len(values))
+	var running float64
+	for i, w := range weights {
+		running += w / total
+		cdf[i] = running
+	}
+	cdf[len(cdf)-1] = 1.0 // guard against float drift
+
+	return weighted{values: values, cdf: cdf}
+}
+
+func (w weighted) sample(stream *prfStream) uint64 {
+	u := stream.float64()
+	i := sort.Search(len(w.cdf), func(i int) bool { return w.cdf[i] >= u })
+	if i == len(w.cdf) {
+		i = len(w.cdf) - 1
+	}
+	return w.values[i]
+}
+
+// Encoder implements testing1.Encoder.
+type Encoder struct {
+	maxLen       uint16
+	lengths      weighted
+	lengthStream *prfStream
+	sleeps       weighted
+	sleepStream  *prfStream
+}
+
+// NewEncoder derives an Encoder from seed, drawing packet lengths from
+// [minLen, maxLen].
+func NewEncoder(seed [SeedLen]byte, minLen, maxLen uint16) (*Encoder, error) {
+	if minLen == 0 || minLen > maxLen {
+		return nil, errors.New("wdist: invalid length range")
+	}
+
+	lengthStream := newPRFStream(seed, domainLength)
+	sleepStream := newPRFStream(seed, domainSleep)
+	return &Encoder{
+		maxLen:       maxLen,
+		lengths:      newWeighted(lengthStream, uint64(minLen), uint64(maxLen)),
+		lengthStream: lengthStream,
+		sleeps:       newWeighted(sleepStream, 0, uint64(maxSleep)),
+		sleepStream:  sleepStream,
+	}, nil
+}
+
+func (e *Encoder) MaxPacketLength() uint16 { return e.maxLen }
+
+func (e *Encoder) NextPacketLength() uint16 {
+	return uint16(e.lengths.sample(e.lengthStream))
+}
+
+func (e *Encoder) NextPacketSleep() time.Duration {
+	return time.Duration(e.sleeps.sample(e.sleepStream))
+}
+
+func (e *Encoder) ShapeBytes(dst, src []byte) (dn, sn int) {
+	n := copy(dst, src)
+	return n, n
+}
+
+// Decoder implements testing1.Decoder.
+type Decoder struct{}
+
+// NewDecoder derives a Decoder from seed. seed is currently unused, but
+// accepted so an Encoder/Decoder pair is always provisioned identically.
+func NewDecoder(seed [SeedLen]byte) *Decoder {
+	return &Decoder{}
+}
+
+func (d *Decoder) UnshapeBytes(dst, src []byte) (dn, sn int) {
+	n := copy(dst, src)
+	return n, n
+}
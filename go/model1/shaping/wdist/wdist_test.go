@@ -0,0 +1,42 @@
+package wdist_test
+
+import (
+	"testing"
+
+	"github.com/DasyatidPrime/Dust/go/model1/shaping/wdist"
+	testing1 "github.com/DasyatidPrime/Dust/go/model1/testing"
+)
+
+const (
+	testMinLen = 64
+	testMaxLen = 1400
+)
+
+func newPair(t *testing.T, seed byte) (*wdist.Encoder, *wdist.Decoder) {
+	var key [wdist.SeedLen]byte
+	for i := range key {
+		key[i] = seed + byte(i)
+	}
+
+	enc, err := wdist.NewEncoder(key, testMinLen, testMaxLen)
+	if err != nil {
+		t.Fatalf("NewEncoder: %v", err)
+	}
+	return enc, wdist.NewDecoder(key)
+}
+
+func TestOneDirection(t *testing.T) {
+	enc, dec := newPair(t, 0x11)
+	testing1.TestOneDirection(t, enc, dec)
+}
+
+func TestBothDirections(t *testing.T) {
+	encA, decA := newPair(t, 0x11)
+	encB, decB := newPair(t, 0x22)
+	testing1.TestBothDirections(t, encA, decA, encB, decB)
+}
+
+func TestFramedRoundTrip(t *testing.T) {
+	enc, dec := newPair(t, 0x33)
+	testing1.TestFramedRoundTrip(t, enc, dec)
+}
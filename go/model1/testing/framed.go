@@ -0,0 +1,67 @@
+package testing1
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DasyatidPrime/Dust/go/model1/shaping/framing"
+)
+
+// framedTrials returns chunk sets TestFramedRoundTrip exercises: an empty
+// chunk, a mix of small/prime/large chunk sizes, and several
+// maximum-packet-length chunks in a row, all against the given Encoder's
+// MaxPacketLength.
+func framedTrials(t *testing.T, max int) [][][]byte {
+	return [][][]byte{
+		{randomBytes(t, 0)},
+		{randomBytes(t, 1), randomBytes(t, max), randomBytes(t, 0)},
+		{randomBytes(t, 17), randomBytes(t, 613), randomBytes(t, 3*max)},
+		{randomBytes(t, max), randomBytes(t, max), randomBytes(t, max)},
+	}
+}
+
+// TestFramedRoundTrip generates random chunk sets, frames them with
+// framing.Framer, shapes the framed bytes through enc, unshapes them
+// through dec, deframes the result with framing.Deframer, and asserts the
+// recovered chunks match the originals byte-for-byte. Unlike
+// TestExpectedPerformance's raw uniform-byte check, this catches a shaper
+// that silently reorders or drops bytes at packet boundaries.
+func TestFramedRoundTrip(t *testing.T, enc Encoder, dec Decoder) {
+	max := int(enc.MaxPacketLength())
+
+	for _, chunkSet := range framedTrials(t, max) {
+		framer := framing.NewFramer()
+		for _, c := range chunkSet {
+			framer.Put(c)
+		}
+
+		var framed []byte
+		buf := make([]byte, max)
+		for framer.Buffered() > 0 {
+			framed = append(framed, buf[:framer.Read(buf)]...)
+		}
+
+		shaped := shapeAll(t, enc, framed, max)
+		unshaped := unshapeAll(t, dec, shaped, max, max)
+
+		deframer := framing.NewDeframer()
+		deframer.Write(unshaped)
+		var recovered [][]byte
+		for {
+			chunk, ok := deframer.Next()
+			if !ok {
+				break
+			}
+			recovered = append(recovered, append([]byte(nil), chunk...))
+		}
+
+		if len(recovered) != len(chunkSet) {
+			t.Fatalf("framed round trip: got %d chunks, want %d", len(recovered), len(chunkSet))
+		}
+		for i := range chunkSet {
+			if !bytes.Equal(recovered[i], chunkSet[i]) {
+				t.Fatalf("framed round trip: chunk %d mismatch", i)
+			}
+		}
+	}
+}
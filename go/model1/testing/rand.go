@@ -0,0 +1,70 @@
+package testing1
+
+import (
+	cryptoRand "crypto/rand"
+	"encoding/binary"
+	"flag"
+	randv2 "math/rand/v2"
+	"sync"
+	"testing"
+)
+
+// TestOptions carries cross-cutting configuration for this package's test
+// helpers.
+type TestOptions struct {
+	// Seed, if nonzero, drives a deterministic ChaCha8 source for all
+	// plaintext generated below, instead of seeding once from
+	// crypto/rand. The seed in use is logged either way.
+	Seed uint64
+}
+
+// Options holds the active TestOptions; set it, if at all, before calling
+// into this package.
+var Options TestOptions
+
+var shaperSeedFlag = flag.Uint64("shaper.seed", 0, "deterministic seed for this package's test randomness (0 = seed from crypto/rand and log it)")
+
+var (
+	randOnce sync.Once
+	randSrc  *randv2.ChaCha8
+)
+
+func randSource(t *testing.T) *randv2.ChaCha8 {
+	randOnce.Do(func() {
+		seed := Options.Seed
+		if seed == 0 {
+			seed = *shaperSeedFlag
+		}
+		if seed == 0 {
+			var b [8]byte
+			if _, err := cryptoRand.Read(b[:]); err != nil {
+				t.Fatalf("cannot seed test randomness: %v", err)
+			}
+			seed = binary.LittleEndian.Uint64(b[:])
+		}
+		t.Logf("shaper test randomness seed: %d (set -shaper.seed=%d to replay)", seed, seed)
+
+		var key [32]byte
+		binary.LittleEndian.PutUint64(key[:8], seed)
+		randSrc = randv2.NewChaCha8(key)
+	})
+	return randSrc
+}
+
+func fillRandom(r *randv2.ChaCha8, out []byte) {
+	for len(out) >= 8 {
+		binary.LittleEndian.PutUint64(out, r.Uint64())
+		out = out[8:]
+	}
+	if len(out) > 0 {
+		var tail [8]byte
+		binary.LittleEndian.PutUint64(tail[:], r.Uint64())
+		copy(out, tail[:])
+	}
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	out := make([]byte, n)
+	fillRandom(randSource(t), out)
+	return out
+}
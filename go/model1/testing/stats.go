@@ -0,0 +1,96 @@
+package testing1
+
+import "math"
+
+// PerformanceOptions configures statistical acceptance criteria for a
+// shaper's output byte stream. A zero value for any field disables that
+// check.
+type PerformanceOptions struct {
+	ChiSquaredAlpha    float64 // significance level for a chi-squared uniformity test; 0 disables
+	EntropyFloorBits   float64 // minimum acceptable Shannon entropy in bits/byte; 0 disables
+	MaxAutocorrelation float64 // max acceptable |autocorrelation| at lags 1..autocorrelationMaxLag; 0 disables
+}
+
+// DefaultPerformanceOptions returns the acceptance criteria
+// TestExpectedPerformance applies.
+func DefaultPerformanceOptions() PerformanceOptions {
+	return PerformanceOptions{
+		ChiSquaredAlpha:    1e-6,
+		EntropyFloorBits:   7.98,
+		MaxAutocorrelation: 0.02,
+	}
+}
+
+const autocorrelationMaxLag = 4
+
+// requiredUniformSampleSize returns the minimum sample size giving the
+// chi-squared test adequate power at significance level alpha.
+func requiredUniformSampleSize(alpha float64) int {
+	const bins = 256
+	perBin := 20.0
+	if alpha > 0 {
+		if scaled := 5 * math.Log10(1/alpha); scaled > perBin {
+			perBin = scaled
+		}
+	}
+	return int(math.Ceil(perBin * bins))
+}
+
+func chiSquaredStatistic(dist [256]uint64, total uint64) float64 {
+	expected := float64(total) / 256.0
+	var stat float64
+	for _, n := range dist {
+		d := float64(n) - expected
+		stat += d * d / expected
+	}
+	return stat
+}
+
+// chiSquaredPValue approximates the upper-tail p-value via the
+// Wilson-Hilferty transformation, under which (stat/df)^(1/3) is
+// approximately normal.
+func chiSquaredPValue(stat float64, df int) float64 {
+	k := float64(df)
+	z := (math.Pow(stat/k, 1.0/3.0) - (1 - 2/(9*k))) / math.Sqrt(2/(9*k))
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
+
+func shannonEntropy(dist [256]uint64, total uint64) float64 {
+	var h float64
+	for _, n := range dist {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func autocorrelation(sample []byte, lag int) float64 {
+	n := len(sample) - lag
+	if n <= 0 {
+		return 0
+	}
+
+	var mean float64
+	for _, b := range sample {
+		mean += float64(b)
+	}
+	mean /= float64(len(sample))
+
+	var den float64
+	for _, b := range sample {
+		d := float64(b) - mean
+		den += d * d
+	}
+	if den == 0 {
+		return 0
+	}
+
+	var num float64
+	for i := 0; i < n; i++ {
+		num += (float64(sample[i]) - mean) * (float64(sample[i+lag]) - mean)
+	}
+	return num / den
+}
@@ -1,7 +1,7 @@
 package testing1
 
 import (
-	cryptoRand "crypto/rand"
+	"bytes"
 	"fmt"
 	"math"
 	"strings"
@@ -27,7 +27,16 @@ type Decoder interface {
 	UnshapeBytes(dst, src []byte) (dn, sn int)
 }
 
+// TestExpectedPerformance runs TestExpectedPerformanceWithOptions with
+// DefaultPerformanceOptions.
 func TestExpectedPerformance(t *testing.T, enc Encoder) {
+	TestExpectedPerformanceWithOptions(t, enc, DefaultPerformanceOptions())
+}
+
+// TestExpectedPerformanceWithOptions is TestExpectedPerformance with
+// caller-supplied statistical acceptance criteria for the shaped byte
+// stream; see PerformanceOptions.
+func TestExpectedPerformanceWithOptions(t *testing.T, enc Encoder, opts PerformanceOptions) {
 	max := enc.MaxPacketLength()
 	var totalBytes uint64
 	var totalDuration time.Duration
@@ -55,22 +64,25 @@ func TestExpectedPerformance(t *testing.T, enc Encoder) {
 	bitsPerSecond := 8.0 * bytesPerSecond
 	t.Logf("simulated average shaped transfer rate: %0.2e B/s = %0.2e b/s (%d B / %0.2f s; granularity %0.3f s)", bytesPerSecond, bitsPerSecond, totalBytes, totalSeconds, totalSeconds / float64(packetIterations))
 
-	uniformSource := make([]byte, uniformChunkLen)
-	_, err := cryptoRand.Read(uniformSource)
-	if err != nil {
-		t.Fatalf("cannot get random bytes: %v", err)
+	chunkCount := uniformChunkCount
+	if opts.ChiSquaredAlpha > 0 {
+		required := requiredUniformSampleSize(opts.ChiSquaredAlpha)
+		if need := (required + uniformChunkLen - 1) / uniformChunkLen; need > chunkCount {
+			chunkCount = need
+		}
 	}
 
+	uniformSource := make([]byte, uniformChunkLen)
+
 	var shapedDist [256]uint64
+	var shapedSample []byte
 
-	totalSource := uniformChunkLen * uniformChunkCount
+	totalSource := uniformChunkLen * chunkCount
 	var sourceConsumed int
 	var shapedProduced int
 	shapedOut := make([]byte, uniformChunkLen)
-	for i := 0; i < uniformChunkCount; i++ {
-		// If we can't read any more random bytes, just reuse the existing ones---this is only a
-		// rough statistical test, so that should be okay so long as the chunks are long enough.
-		_, _ = cryptoRand.Read(uniformSource)
+	for i := 0; i < chunkCount; i++ {
+		fillRandom(randSource(t), uniformSource)
 		tail := uniformSource
 		for len(tail) > 0 {
 			dn, sn := enc.ShapeBytes(shapedOut, tail)
@@ -90,6 +102,7 @@ func TestExpectedPerformance(t *testing.T, enc Encoder) {
 			for _, b := range shapedOut[:dn] {
 				shapedDist[b]++
 			}
+			shapedSample = append(shapedSample, shapedOut[:dn]...)
 
 			sourceConsumed += sn
 			shapedProduced += dn
@@ -116,8 +129,148 @@ func TestExpectedPerformance(t *testing.T, enc Encoder) {
 		}
 	}
 	t.Logf("shaped byte distribution -log: %s", strings.Join(shapedDistDisplay[:], " "))
+
+	if opts.ChiSquaredAlpha > 0 {
+		const df = 255 // 256 bins, one degree of freedom spent on the total
+		stat := chiSquaredStatistic(shapedDist, uint64(shapedProduced))
+		p := chiSquaredPValue(stat, df)
+		t.Logf("shaped byte distribution chi-squared: stat=%0.2f p~=%0.3g", stat, p)
+		if p < opts.ChiSquaredAlpha {
+			t.Fatalf("shaped byte distribution rejected as non-uniform: chi-squared p~=%0.3g < alpha=%0.3g", p, opts.ChiSquaredAlpha)
+		}
+	}
+
+	if opts.EntropyFloorBits > 0 {
+		entropy := shannonEntropy(shapedDist, uint64(shapedProduced))
+		t.Logf("shaped byte distribution entropy: %0.4f bits/byte", entropy)
+		if entropy < opts.EntropyFloorBits {
+			t.Fatalf("shaped byte distribution entropy %0.4f bits/byte < floor %0.4f", entropy, opts.EntropyFloorBits)
+		}
+	}
+
+	if opts.MaxAutocorrelation > 0 {
+		for lag := 1; lag <= autocorrelationMaxLag; lag++ {
+			ac := autocorrelation(shapedSample, lag)
+			t.Logf("shaped byte stream autocorrelation at lag %d: %+0.4f", lag, ac)
+			if math.Abs(ac) > opts.MaxAutocorrelation {
+				t.Fatalf("shaped byte stream autocorrelation at lag %d is %+0.4f, exceeds %0.4f", lag, ac, opts.MaxAutocorrelation)
+			}
+		}
+	}
 }
 
 func TestOneDirection(t *testing.T, enc Encoder, dec Decoder) {
 	TestExpectedPerformance(t, enc)
+	testRoundTrip(t, enc, dec)
+}
+
+// TestBothDirections runs testRoundTrip over both (encA, decB) and (encB,
+// decA), then interleaves repeated passes over the same instances so that
+// state a shaper keeps per direction (sequence counters, buffered partial
+// frames, PRF stream position, and so on) can't leak across directions
+// without a round trip eventually producing the wrong bytes.
+func TestBothDirections(t *testing.T, encA Encoder, decA Decoder, encB Encoder, decB Decoder) {
+	t.Run("a-to-b", func(t *testing.T) { testRoundTrip(t, encA, decB) })
+	t.Run("b-to-a", func(t *testing.T) { testRoundTrip(t, encB, decA) })
+	t.Run("interleaved", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			testRoundTrip(t, encA, decB)
+			testRoundTrip(t, encB, decA)
+		}
+	})
+}
+
+// roundTripBufferSizes returns the destination/source buffer sizes used to
+// adversarially vary how testRoundTrip feeds an Encoder/Decoder pair: one
+// byte at a time, a prime size that won't evenly divide packet or chunk
+// boundaries, exactly max, and a buffer much larger than any single
+// packet.
+func roundTripBufferSizes(max int) []int {
+	return []int{1, 97, max, 8*max + 1}
+}
+
+// testRoundTrip feeds random plaintext through enc.ShapeBytes and
+// dec.UnshapeBytes across a cross product of adversarial buffer sizes,
+// asserting the recovered plaintext is byte-for-byte identical to the
+// original. The cross product's one-byte source size feeds the decoder
+// shaped bytes one byte at a time. It also pins an edge case the cross
+// product wouldn't otherwise reach: a starved encoder (empty src, nonzero
+// dst).
+func testRoundTrip(t *testing.T, enc Encoder, dec Decoder) {
+	max := int(enc.MaxPacketLength())
+
+	starvedDst := make([]byte, max)
+	if dn, sn := enc.ShapeBytes(starvedDst, nil); sn != 0 {
+		t.Fatalf("starved encoder claims to have consumed %d bytes from empty src (produced %d)", sn, dn)
+	}
+
+	plaintext := randomBytes(t, 4*max+997) // several packets plus a prime-sized remainder
+
+	for _, shapeDst := range roundTripBufferSizes(max) {
+		shaped := shapeAll(t, enc, plaintext, shapeDst)
+
+		for _, unshapeDst := range roundTripBufferSizes(max) {
+			for _, unshapeSrc := range roundTripBufferSizes(max) {
+				recovered := unshapeAll(t, dec, shaped, unshapeDst, unshapeSrc)
+				if !bytes.Equal(recovered, plaintext) {
+					t.Fatalf("round trip mismatch (shapeDst=%d unshapeDst=%d unshapeSrc=%d): got %d bytes, want %d",
+						shapeDst, unshapeDst, unshapeSrc, len(recovered), len(plaintext))
+				}
+			}
+		}
+	}
+}
+
+// shapeAll runs src through enc.ShapeBytes to completion using a dst
+// buffer of the given size, concatenating every produced chunk.
+func shapeAll(t *testing.T, enc Encoder, src []byte, dstSize int) []byte {
+	var out []byte
+	dst := make([]byte, dstSize)
+	for len(src) > 0 {
+		dn, sn := enc.ShapeBytes(dst, src)
+		checkProgress(t, "ShapeBytes", dn, sn, len(dst), len(src))
+		out = append(out, dst[:dn]...)
+		src = src[sn:]
+	}
+	return out
+}
+
+// unshapeAll runs shaped through dec.UnshapeBytes to completion, using a
+// dst buffer of dstSize and feeding src in chunks of at most srcSize bytes
+// at a time, concatenating every produced chunk.
+func unshapeAll(t *testing.T, dec Decoder, shaped []byte, dstSize, srcSize int) []byte {
+	var out []byte
+	dst := make([]byte, dstSize)
+	for len(shaped) > 0 {
+		chunk := shaped
+		if len(chunk) > srcSize {
+			chunk = chunk[:srcSize]
+		}
+		dn, sn := dec.UnshapeBytes(dst, chunk)
+		checkProgress(t, "UnshapeBytes", dn, sn, len(dst), len(chunk))
+		out = append(out, dst[:dn]...)
+		shaped = shaped[sn:]
+	}
+	return out
+}
+
+// checkProgress asserts the (dn, sn) contract shared by ShapeBytes and
+// UnshapeBytes: both must be non-negative and within the bounds of the
+// buffers they describe, and since dst and src here are always nonempty,
+// at least one of dn, sn must be positive. dn == 0 && sn > 0 (buffering
+// without emitting) and dn > 0 && sn == 0 (emitting buffered output
+// without consuming) are both legal and deliberately not rejected here.
+func checkProgress(t *testing.T, who string, dn, sn, dstLen, srcLen int) {
+	switch {
+	case dn == 0 && sn == 0:
+		t.Fatalf("%s made no progress", who)
+	case dn < 0:
+		t.Fatalf("%s claims to have produced %d bytes", who, dn)
+	case sn < 0:
+		t.Fatalf("%s claims to have consumed %d bytes", who, sn)
+	case dn > dstLen:
+		t.Fatalf("%s claims to have produced %d > %d bytes", who, dn, dstLen)
+	case sn > srcLen:
+		t.Fatalf("%s claims to have consumed %d > %d bytes", who, sn, srcLen)
+	}
 }